@@ -0,0 +1,32 @@
+// Package httperr writes RFC 7807 application/problem+json error
+// responses, so clients get machine-readable errors instead of plain
+// text.
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// problem is the application/problem+json body WriteProblem writes.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// WriteProblem writes an RFC 7807 application/problem+json response with
+// status, title, detail, and instance (typically the request path).
+func WriteProblem(w http.ResponseWriter, status int, title, detail, instance string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+	})
+}
@@ -1,165 +1,150 @@
 package main
 
 import (
-	"encoding/json" // Provides functions to encode and decode JSON data.
-	"fmt"           // Provides formatted I/O functions like Println, Printf, etc.
-	"net/http"      // Provides HTTP client and server implementations.
-	"strconv"       // Provides functions to convert strings to numbers and vice versa.
-	"sync"          // Provides synchronization primitives such as mutexes for safe concurrent access.
+	"context"   // Used to bound how long graceful shutdown waits.
+	"flag"      // Parses command-line flags such as -data and -backend.
+	"fmt"       // Provides formatted I/O functions like Println, Printf, etc.
+	"log"       // Logs startup, shutdown, and persistence errors.
+	"net/http"  // Provides HTTP client and server implementations.
+	"os"        // Reads the USERS_FILE environment variable.
+	"os/signal" // Notifies on SIGINT/SIGTERM for graceful shutdown.
+	"syscall"   // Identifies the SIGTERM signal.
+	"time"      // Bounds the graceful shutdown timeout.
+
+	"github.com/Khroxx/http-go/middleware"
 )
 
 // User struct represents a user with a single field `Name`.
 // The `json:"name"` tag specifies how this field will be encoded/decoded in JSON.
 type User struct {
+	ID   int    `json:"id,omitempty"`
 	Name string `json:"name"`
 }
 
-// userCache is a map that stores users with an integer key.
-// This acts as an in-memory storage for user data.
-var userCache = make(map[int]User)
-
-// cacheMutex is a read-write mutex used to synchronize access to the userCache.
-// This ensures thread-safe operations on the map.
-var cacheMutex sync.RWMutex
-
-// main is the entry point of the program.
-func main() {
-	// Create a new HTTP request multiplexer (router).
-	mux := http.NewServeMux()
-
-	// Register a handler for GET requests to the root URL ("/").
-	mux.HandleFunc("/", handleRoot)
-
-	// Register a handler for POST requests to "/users".
-	mux.HandleFunc("POST /users", createUser)
-
-	// Register a handler for GET requests to "/users/{id}".
-	mux.HandleFunc("GET /users/{id}", getUser)
-
-	// Register a handler for DELETE requests to "/users/{id}".
-	mux.HandleFunc("DELETE /users/{id}", deleteUser)
-
-	// Print a message to the console indicating the server is starting.
-	fmt.Println("Server listening to :9090")
-
-	// Start the HTTP server on port 9090 and use the `mux` router.
-	http.ListenAndServe(":9090", mux)
+// sortableFields whitelists the query values accepted by the `sort`
+// parameter on GET /users.
+var sortableFields = map[string]bool{
+	"id":   true,
+	"name": true,
 }
 
-// handleRoot handles GET requests to the root URL ("/").
-// It responds with "Hello World".
-func handleRoot(
-	w http.ResponseWriter, // Used to send a response back to the client.
-	r *http.Request, // Represents the incoming HTTP request.
-) {
-	// Write "Hello World" to the response.
-	fmt.Fprintf(w, "Hello World")
-}
+// defaultDataFile is used when neither -data nor USERS_FILE is set.
+const defaultDataFile = "users.json"
 
-// getUser handles GET requests to "/users/{id}".
-// It retrieves a user by their ID from the userCache.
-func getUser(
-	w http.ResponseWriter, // Used to send a response back to the client.
-	r *http.Request, // Represents the incoming HTTP request.
-) {
-	// Extract the user ID from the URL path.
-	id, err := strconv.Atoi(r.URL.Path[len("/users/"):])
-	if err != nil {
-		// If the ID is not a valid integer, return a 400 Bad Request error.
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
+// defaultMaxInFlight is the concurrency limit used when -max-inflight is
+// not set.
+const defaultMaxInFlight = 100
 
-	// Lock the cache for reading and retrieve the user.
-	cacheMutex.RLock()
-	user, ok := userCache[id]
-	cacheMutex.RUnlock()
+// shutdownTimeout bounds how long shutdown waits for in-flight requests
+// to drain before forcing the listener closed.
+const shutdownTimeout = 5 * time.Second
 
-	if !ok {
-		// If the user is not found, return a 404 Not Found error.
-		http.Error(w, "user not found", http.StatusNotFound)
-		return
+// main is the entry point of the program.
+func main() {
+	// USERS_FILE sets the default so -data only needs to be passed when
+	// overriding it for a single run.
+	defaultPath := defaultDataFile
+	if v := os.Getenv("USERS_FILE"); v != "" {
+		defaultPath = v
 	}
+	data := flag.String("data", defaultPath, "path to the file used to store users (JSON path for -backend=memory, database path for -backend=sqlite)")
+	backend := flag.String("backend", "memory", "storage backend to use: memory or sqlite")
+	maxInFlight := flag.Int("max-inflight", defaultMaxInFlight, "maximum number of requests handled concurrently before returning 503")
+	flag.Parse()
 
-	// Convert the user to JSON and send it in the response.
-	w.Header().Set("Content-Type", "application/json")
-	j, err := json.Marshal(user)
+	store, closeStore, err := newStore(*backend, *data)
 	if err != nil {
-		// If there is an error during JSON encoding, return a 500 Internal Server Error.
-		// This is a server-side error, so we use http.StatusInternalServerError.
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		log.Fatalf("failed to initialize %s backend: %v", *backend, err)
 	}
-
-	// Write the JSON response with a 200 OK status.
-	w.WriteHeader(http.StatusOK)
-	w.Write(j)
-}
-
-// createUser handles POST requests to "/users".
-// It creates a new user and stores it in the userCache.
-func createUser(
-	w http.ResponseWriter, // Used to send a response back to the client.
-	r *http.Request, // Represents the incoming HTTP request.
-) {
-	// Decode the JSON body into a User struct.
-	var user User
-	err := json.NewDecoder(r.Body).Decode(&user)
-	if err != nil {
-		// If the request body is not valid JSON, return a 400 Bad Request error.
-		http.Error(
-			w,
-			err.Error(),
-			http.StatusBadRequest,
-		)
-		return
+	defer closeStore()
+
+	counter := middleware.NewRequestCounter(*maxInFlight)
+	srv := NewServer(store, counter)
+
+	// Recover sits closest to the mux so a handler panic is turned into a
+	// 500 before Metrics and Logging record the final status. Limit sits
+	// just outside Recover so "in flight" tracks requests actually being
+	// handled, not time spent in RequestID/Logging/Metrics. RequestID is
+	// outermost so its id is available to everything downstream.
+	handler := middleware.RequestID(
+		middleware.Logging(
+			middleware.Metrics(
+				counter.Limit(
+					middleware.Recover(srv.Routes()),
+				),
+			),
+		),
+	)
+	httpServer := &http.Server{Addr: ":9090", Handler: handler}
+
+	// Run the server in the background so main can wait for a shutdown
+	// signal below.
+	go func() {
+		// Print a message to the console indicating the server is starting.
+		fmt.Println("Server listening to :9090")
+
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
+
+	// Block until SIGINT or SIGTERM, then drain and persist before exiting.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	// Shutdown closes the listener immediately so no new connections are
+	// accepted, then blocks until active connections finish or ctx
+	// expires; run it in the background so we can drain our own counter
+	// (and log if that times out) while it does.
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- httpServer.Shutdown(ctx) }()
+
+	if !counter.Drain(shutdownTimeout) {
+		log.Printf("shutdown timed out waiting for %d in-flight request(s) to drain", counter.Current())
 	}
 
-	// Validate that the user's name is not empty.
-	if user.Name == "" {
-		http.Error(w, "Name is required", http.StatusBadRequest)
-		return
+	if mem, ok := store.(*memStore); ok {
+		if err := mem.Save(); err != nil {
+			log.Printf("failed to persist users on shutdown: %v", err)
+		}
 	}
 
-	// Lock the cache for writing and add the new user.
-	cacheMutex.Lock()
-	userCache[len(userCache)+1] = user
-	cacheMutex.Unlock()
+	if err := <-shutdownErr; err != nil {
+		log.Printf("graceful shutdown failed: %v", err)
+	}
+}
 
-	// Respond with a 204 No Content status to indicate success.
-	w.WriteHeader(http.StatusNoContent)
+// newStore constructs the Store for the named backend, recovering any
+// state it needs before handlers start serving requests. closeFn
+// releases backend resources on shutdown and is never nil.
+func newStore(backend, dataFile string) (store Store, closeFn func() error, err error) {
+	switch backend {
+	case "memory":
+		mem := newMemStore(dataFile)
+		if err := mem.Load(); err != nil {
+			return nil, nil, err
+		}
+		return mem, func() error { return nil }, nil
+	case "sqlite":
+		sq, err := newSQLiteStore(dataFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sq, sq.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown backend %q", backend)
+	}
 }
 
-// deleteUser handles DELETE requests to "/users/{id}".
-// It deletes a user by their ID from the userCache.
-func deleteUser(
+// handleRoot handles GET requests to the root URL ("/").
+// It responds with "Hello World".
+func handleRoot(
 	w http.ResponseWriter, // Used to send a response back to the client.
 	r *http.Request, // Represents the incoming HTTP request.
 ) {
-	// Extract the user ID from the URL path.
-	id, err := strconv.Atoi(r.URL.Path[len("/users/"):])
-	if err != nil {
-		// If the ID is not a valid integer, return a 400 Bad Request error.
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	// Check if the user exists in the cache.
-	cacheMutex.RLock()
-	_, ok := userCache[id]
-	cacheMutex.RUnlock()
-
-	if !ok {
-		// If the user is not found, return a 404 Not Found error.
-		http.Error(w, "user not found", http.StatusBadRequest)
-		return
-	}
-
-	// Lock the cache for writing and delete the user.
-	cacheMutex.Lock()
-	delete(userCache, id)
-	cacheMutex.Unlock()
-
-	// Respond with a 204 No Content status to indicate success.
-	w.WriteHeader(http.StatusNoContent)
+	// Write "Hello World" to the response.
+	fmt.Fprintf(w, "Hello World")
 }
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Khroxx/http-go/middleware"
+)
+
+// newTestServer returns an httptest.Server backed by a fresh, unpersisted
+// memStore so tests stay black-box against http.Handler and don't touch
+// disk.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	store := newMemStore("")
+	srv := NewServer(store, middleware.NewRequestCounter(100))
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// createUser posts body to /users and returns the response status code.
+// It reports failures with Errorf rather than Fatalf so it's safe to call
+// from the goroutines TestConcurrentCreate spawns.
+func createUser(t *testing.T, ts *httptest.Server, body string) int {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/users", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Errorf("POST /users: %v", err)
+		return -1
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestCreateGetUser(t *testing.T) {
+	ts := newTestServer(t)
+
+	if status := createUser(t, ts, `{"name":"alice"}`); status != http.StatusNoContent {
+		t.Fatalf("POST /users: got status %d, want %d", status, http.StatusNoContent)
+	}
+
+	resp, err := http.Get(ts.URL + "/users/1")
+	if err != nil {
+		t.Fatalf("GET /users/1: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /users/1: got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var user User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if user.Name != "alice" {
+		t.Errorf("got name %q, want %q", user.Name, "alice")
+	}
+}
+
+func TestCreateUserErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"malformed JSON", `{"name":`, http.StatusBadRequest},
+		{"empty name", `{"name":""}`, http.StatusBadRequest},
+		{"missing name field", `{}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			if status := createUser(t, ts, tt.body); status != tt.wantStatus {
+				t.Errorf("POST /users: got status %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGetUserErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"non-integer id", "/users/abc", http.StatusBadRequest},
+		{"missing id", "/users/999", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET %s: %v", tt.path, err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET %s: got status %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	ts := newTestServer(t)
+	createUser(t, ts, `{"name":"bob"}`)
+	createUser(t, ts, `{"name":"alice"}`)
+
+	resp, err := http.Get(ts.URL + "/users?sort=name")
+	if err != nil {
+		t.Fatalf("GET /users: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /users: got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var users []User
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "alice" || users[1].Name != "bob" {
+		t.Errorf("got %+v, want [alice bob] sorted by name", users)
+	}
+}
+
+func TestListUsersInvalidSort(t *testing.T) {
+	ts := newTestServer(t)
+	resp, err := http.Get(ts.URL + "/users?sort=bogus")
+	if err != nil {
+		t.Fatalf("GET /users: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("GET /users?sort=bogus: got status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestPutUser(t *testing.T) {
+	ts := newTestServer(t)
+	createUser(t, ts, `{"name":"alice"}`)
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+"/users/1", bytes.NewBufferString(`{"name":"alicia"}`))
+	if err != nil {
+		t.Fatalf("build PUT request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT /users/1: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("PUT /users/1: got status %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+
+	resp, err = http.Get(ts.URL + "/users/1")
+	if err != nil {
+		t.Fatalf("GET /users/1: %v", err)
+	}
+	defer resp.Body.Close()
+	var user User
+	json.NewDecoder(resp.Body).Decode(&user)
+	if user.Name != "alicia" {
+		t.Errorf("got name %q, want %q", user.Name, "alicia")
+	}
+}
+
+func TestPutUserNotFound(t *testing.T) {
+	ts := newTestServer(t)
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+"/users/999", bytes.NewBufferString(`{"name":"nobody"}`))
+	if err != nil {
+		t.Fatalf("build PUT request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT /users/999: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("PUT /users/999: got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestDeleteThenGet(t *testing.T) {
+	ts := newTestServer(t)
+	createUser(t, ts, `{"name":"alice"}`)
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/users/1", nil)
+	if err != nil {
+		t.Fatalf("build DELETE request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE /users/1: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE /users/1: got status %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+
+	resp, err = http.Get(ts.URL + "/users/1")
+	if err != nil {
+		t.Fatalf("GET /users/1: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /users/1 after delete: got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestDoubleDelete(t *testing.T) {
+	ts := newTestServer(t)
+	createUser(t, ts, `{"name":"alice"}`)
+
+	del := func() int {
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/users/1", nil)
+		if err != nil {
+			t.Fatalf("build DELETE request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("DELETE /users/1: %v", err)
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := del(); status != http.StatusNoContent {
+		t.Fatalf("first DELETE /users/1: got status %d, want %d", status, http.StatusNoContent)
+	}
+	if status := del(); status != http.StatusNotFound {
+		t.Errorf("second DELETE /users/1: got status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+// TestConcurrentCreate fires N concurrent POST /users requests and
+// asserts the final user count equals N, guarding against the id
+// collisions len(userCache)+1 used to allow.
+func TestConcurrentCreate(t *testing.T) {
+	const n = 50
+	ts := newTestServer(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			createUser(t, ts, fmt.Sprintf(`{"name":"user-%d"}`, i))
+		}(i)
+	}
+	wg.Wait()
+
+	resp, err := http.Get(ts.URL + "/users")
+	if err != nil {
+		t.Fatalf("GET /users: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var users []User
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(users) != n {
+		t.Fatalf("got %d users, want %d", len(users), n)
+	}
+
+	seen := make(map[int]bool, n)
+	for _, u := range users {
+		if seen[u.ID] {
+			t.Errorf("duplicate id %d", u.ID)
+		}
+		seen[u.ID] = true
+	}
+}
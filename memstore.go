@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// memStore is the in-memory Store backed by a map and guarded by a
+// RWMutex. When dataFile is set, every mutation is persisted back to it
+// write-through.
+type memStore struct {
+	mu       sync.RWMutex
+	users    map[int]User
+	nextID   int
+	dataFile string // empty disables persistence
+}
+
+// newMemStore creates an empty memStore. Call Load before serving
+// requests to recover any state from a previous run.
+func newMemStore(dataFile string) *memStore {
+	return &memStore{
+		users:    make(map[int]User),
+		nextID:   1,
+		dataFile: dataFile,
+	}
+}
+
+// Load recovers users and the nextID counter from dataFile. A missing
+// file is not an error: it just means there is nothing to recover yet.
+func (s *memStore) Load() error {
+	if s.dataFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.dataFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[int]User)
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = loaded
+	for id := range s.users {
+		if id >= s.nextID {
+			s.nextID = id + 1
+		}
+	}
+	return nil
+}
+
+// Save serializes the cache to dataFile, writing to a temp file in the
+// same directory and renaming it over dataFile so a crash mid-write never
+// leaves a truncated file behind. It is a no-op when persistence is
+// disabled.
+func (s *memStore) Save() error {
+	if s.dataFile == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	data, err := json.Marshal(s.users)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.dataFile), ".users-*.json.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, s.dataFile)
+}
+
+// Get implements Store.
+func (s *memStore) Get(id int) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	user.ID = id
+	return user, nil
+}
+
+// Put implements Store.
+func (s *memStore) Put(id int, user User) error {
+	s.mu.Lock()
+	if _, ok := s.users[id]; !ok {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	s.users[id] = user
+	s.mu.Unlock()
+
+	return s.Save()
+}
+
+// Delete implements Store.
+func (s *memStore) Delete(id int) error {
+	s.mu.Lock()
+	if _, ok := s.users[id]; !ok {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	delete(s.users, id)
+	s.mu.Unlock()
+
+	return s.Save()
+}
+
+// Create implements Store. It assigns ids from a monotonically
+// increasing counter so ids stay unique across the lifetime of the
+// backing file, even after deletes.
+func (s *memStore) Create(user User) (int, error) {
+	s.mu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.users[id] = user
+	s.mu.Unlock()
+
+	if err := s.Save(); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// List implements Store.
+func (s *memStore) List(opts ListOptions) ([]User, error) {
+	s.mu.RLock()
+	entries := make([]User, 0, len(s.users))
+	for id, user := range s.users {
+		user.ID = id
+		entries = append(entries, user)
+	}
+	s.mu.RUnlock()
+
+	switch opts.Sort {
+	case "name":
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	default:
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	}
+
+	if opts.Offset > len(entries) {
+		opts.Offset = len(entries)
+	}
+	entries = entries[opts.Offset:]
+
+	if opts.Limit >= 0 && opts.Limit < len(entries) {
+		entries = entries[:opts.Limit]
+	}
+	return entries, nil
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Khroxx/http-go/httperr"
+)
+
+// RequestCounter caps the number of requests handled concurrently,
+// rejecting anything past the limit with a 503 and a Retry-After header.
+// It also exposes the current in-flight count for status reporting and
+// graceful-shutdown draining, mirroring the counter Arvados keepstore
+// uses to shape concurrency around its handlers.
+type RequestCounter struct {
+	max     int64
+	current int64
+}
+
+// NewRequestCounter returns a RequestCounter that allows up to max
+// requests in flight at once.
+func NewRequestCounter(max int) *RequestCounter {
+	return &RequestCounter{max: int64(max)}
+}
+
+// Limit wraps next, rejecting requests once Current would exceed Max.
+func (c *RequestCounter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&c.current, 1) > c.max {
+			atomic.AddInt64(&c.current, -1)
+			w.Header().Set("Retry-After", "1")
+			httperr.WriteProblem(w, http.StatusServiceUnavailable, "Service Unavailable", "too many concurrent requests", r.URL.Path)
+			return
+		}
+		defer atomic.AddInt64(&c.current, -1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Current returns the number of requests currently in flight.
+func (c *RequestCounter) Current() int {
+	return int(atomic.LoadInt64(&c.current))
+}
+
+// Max returns the configured concurrency limit.
+func (c *RequestCounter) Max() int {
+	return int(c.max)
+}
+
+// Drain blocks until Current reaches zero or timeout elapses, whichever
+// comes first, returning true if it drained cleanly.
+func (c *RequestCounter) Drain(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for c.Current() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// accessLogEntry is the JSON shape Logging writes per request.
+type accessLogEntry struct {
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	RequestID  string  `json:"request_id"`
+}
+
+// Logging emits one JSON line per request to the standard logger with
+// the method, path, status, duration, and request id.
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		entry := accessLogEntry{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Status:     rec.status,
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			RequestID:  RequestIDFromContext(r.Context()),
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Printf("failed to marshal access log entry: %v", err)
+			return
+		}
+		log.Println(string(line))
+	})
+}
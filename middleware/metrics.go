@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests by method, path, and status.",
+		},
+		[]string{"method", "path", "status"},
+	)
+
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request duration in seconds.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "path", "status"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(requestsTotal, requestDuration)
+}
+
+// Metrics records http_requests_total and http_request_duration_seconds
+// for every request, labeled by method, path, and status.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		status := strconv.Itoa(rec.status)
+		path := routeLabel(r.URL.Path)
+		method := methodLabel(r.Method)
+		requestsTotal.WithLabelValues(method, path, status).Inc()
+		requestDuration.WithLabelValues(method, path, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeLabel collapses a request path to the route template it matches,
+// so ids and other path parameters don't each mint their own Prometheus
+// time series. Paths that don't match a known route are bucketed as
+// "other" to keep cardinality bounded even against arbitrary client input.
+func routeLabel(path string) string {
+	switch path {
+	case "/", "/users", "/status.json", "/metrics":
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, "/users/"); ok && rest != "" && !strings.Contains(rest, "/") {
+		return "/users/{id}"
+	}
+	return "other"
+}
+
+// knownMethods whitelists the HTTP methods the mux actually routes on.
+var knownMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// methodLabel bounds the method label the same way routeLabel bounds the
+// path label: a client sending arbitrary request-line methods shouldn't
+// be able to mint new time series.
+func methodLabel(method string) string {
+	if knownMethods[method] {
+		return method
+	}
+	return "OTHER"
+}
+
+// Handler exposes the registered metrics for scraping at /metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import "net/http"
+
+// statusRecorder wraps http.ResponseWriter to capture the status code
+// written, since http.ResponseWriter doesn't expose it otherwise.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records status before delegating to the wrapped writer.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
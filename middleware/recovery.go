@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/Khroxx/http-go/httperr"
+)
+
+// Recover turns a panic anywhere in next into a 500 response and logs
+// the stack trace, instead of taking down the whole server.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Printf("panic handling %s %s: %v\n%s", r.Method, r.URL.Path, err, debug.Stack())
+				httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", "internal server error", r.URL.Path)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// requestIDHeader is the header checked for a caller-supplied request id
+// and echoed back on the response.
+const requestIDHeader = "X-Request-ID"
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// RequestID reads the X-Request-ID header from the incoming request,
+// generating a UUID when it is absent, and stores it on the request
+// context so later middleware and handlers can retrieve it via
+// RequestIDFromContext.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the request id stored by RequestID, or ""
+// if none is present.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Khroxx/http-go/httperr"
+	"github.com/Khroxx/http-go/middleware"
+)
+
+// Server wires HTTP handlers to a Store, so handlers never reach into
+// package-level state and tests can inject a fake backend.
+type Server struct {
+	store     Store
+	counter   *middleware.RequestCounter
+	startedAt time.Time
+}
+
+// NewServer returns a Server backed by store, reporting in-flight counts
+// from counter on /status.json.
+func NewServer(store Store, counter *middleware.RequestCounter) *Server {
+	return &Server{store: store, counter: counter, startedAt: time.Now()}
+}
+
+// Routes builds the mux, wiring every handler to s.
+func (s *Server) Routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Register a handler for GET requests to the root URL ("/").
+	mux.HandleFunc("/", handleRoot)
+
+	// Register a handler for POST requests to "/users".
+	mux.HandleFunc("POST /users", s.createUser)
+
+	// Register a handler for GET requests to "/users", listing every user.
+	mux.HandleFunc("GET /users", s.listUsers)
+
+	// Register a handler for GET requests to "/users/{id}".
+	mux.HandleFunc("GET /users/{id}", s.getUser)
+
+	// Register a handler for PUT requests to "/users/{id}".
+	mux.HandleFunc("PUT /users/{id}", s.putUser)
+
+	// Register a handler for DELETE requests to "/users/{id}".
+	mux.HandleFunc("DELETE /users/{id}", s.deleteUser)
+
+	// Register a handler for GET requests to "/status.json".
+	mux.HandleFunc("GET /status.json", s.status)
+
+	// Expose Prometheus metrics for scraping.
+	mux.Handle("/metrics", middleware.Handler())
+
+	return mux
+}
+
+// statusResponse is the JSON body served at /status.json.
+type statusResponse struct {
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	UserCount     int     `json:"user_count"`
+	InFlight      int     `json:"in_flight"`
+	MaxInFlight   int     `json:"max_in_flight"`
+}
+
+// status handles GET requests to "/status.json", reporting uptime, user
+// count, and the current concurrency-limiter state.
+func (s *Server) status(
+	w http.ResponseWriter, // Used to send a response back to the client.
+	r *http.Request, // Represents the incoming HTTP request.
+) {
+	users, err := s.store.List(ListOptions{Limit: -1})
+	if err != nil {
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	resp := statusResponse{
+		UptimeSeconds: time.Since(s.startedAt).Seconds(),
+		UserCount:     len(users),
+		InFlight:      s.counter.Current(),
+		MaxInFlight:   s.counter.Max(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	j, err := json.Marshal(resp)
+	if err != nil {
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// getUser handles GET requests to "/users/{id}".
+// It retrieves a user by their ID from the store.
+func (s *Server) getUser(
+	w http.ResponseWriter, // Used to send a response back to the client.
+	r *http.Request, // Represents the incoming HTTP request.
+) {
+	// Extract the user ID from the URL path.
+	id, err := strconv.Atoi(r.URL.Path[len("/users/"):])
+	if err != nil {
+		// If the ID is not a valid integer, return a 400 Bad Request error.
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", err.Error(), r.URL.Path)
+		return
+	}
+
+	user, err := s.store.Get(id)
+	if errors.Is(err, ErrNotFound) {
+		// If the user is not found, return a 404 Not Found error.
+		httperr.WriteProblem(w, http.StatusNotFound, "Not Found", "user not found", r.URL.Path)
+		return
+	}
+	if err != nil {
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Convert the user to JSON and send it in the response.
+	w.Header().Set("Content-Type", "application/json")
+	j, err := json.Marshal(user)
+	if err != nil {
+		// If there is an error during JSON encoding, return a 500 Internal Server Error.
+		// This is a server-side error, so we use http.StatusInternalServerError.
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Write the JSON response with a 200 OK status.
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// listUsers handles GET requests to "/users".
+// It returns every user as a JSON array, honoring `sort`, `limit`, and
+// `offset` query parameters.
+func (s *Server) listUsers(
+	w http.ResponseWriter, // Used to send a response back to the client.
+	r *http.Request, // Represents the incoming HTTP request.
+) {
+	query := r.URL.Query()
+
+	// Default to sorting by id; reject anything outside the whitelist.
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = "id"
+	}
+	if !sortableFields[sortBy] {
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", "invalid sort field", r.URL.Path)
+		return
+	}
+
+	// Parse pagination parameters, defaulting to the full result set.
+	opts := ListOptions{Sort: sortBy, Limit: -1}
+	if raw := query.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", "invalid offset", r.URL.Path)
+			return
+		}
+		opts.Offset = offset
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", "invalid limit", r.URL.Path)
+			return
+		}
+		opts.Limit = limit
+	}
+
+	users, err := s.store.List(opts)
+	if err != nil {
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Convert the users to JSON and send them in the response.
+	w.Header().Set("Content-Type", "application/json")
+	j, err := json.Marshal(users)
+	if err != nil {
+		// If there is an error during JSON encoding, return a 500 Internal Server Error.
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Write the JSON response with a 200 OK status.
+	w.WriteHeader(http.StatusOK)
+	w.Write(j)
+}
+
+// createUser handles POST requests to "/users".
+// It creates a new user in the store.
+func (s *Server) createUser(
+	w http.ResponseWriter, // Used to send a response back to the client.
+	r *http.Request, // Represents the incoming HTTP request.
+) {
+	// Decode the JSON body into a User struct.
+	var user User
+	err := json.NewDecoder(r.Body).Decode(&user)
+	if err != nil {
+		// If the request body is not valid JSON, return a 400 Bad Request error.
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Validate that the user's name is not empty.
+	if user.Name == "" {
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", "Name is required", r.URL.Path)
+		return
+	}
+
+	if _, err := s.store.Create(user); err != nil {
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Respond with a 204 No Content status to indicate success.
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// putUser handles PUT requests to "/users/{id}".
+// It replaces the user stored under id, returning 404 if it doesn't exist.
+func (s *Server) putUser(
+	w http.ResponseWriter, // Used to send a response back to the client.
+	r *http.Request, // Represents the incoming HTTP request.
+) {
+	// Extract the user ID from the URL path.
+	id, err := strconv.Atoi(r.URL.Path[len("/users/"):])
+	if err != nil {
+		// If the ID is not a valid integer, return a 400 Bad Request error.
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Decode the JSON body into a User struct.
+	var user User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		// If the request body is not valid JSON, return a 400 Bad Request error.
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Validate that the user's name is not empty.
+	if user.Name == "" {
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", "Name is required", r.URL.Path)
+		return
+	}
+
+	if err := s.store.Put(id, user); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			// If the user is not found, return a 404 Not Found error.
+			httperr.WriteProblem(w, http.StatusNotFound, "Not Found", "user not found", r.URL.Path)
+			return
+		}
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Respond with a 204 No Content status to indicate success.
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteUser handles DELETE requests to "/users/{id}".
+// It deletes a user by their ID from the store.
+func (s *Server) deleteUser(
+	w http.ResponseWriter, // Used to send a response back to the client.
+	r *http.Request, // Represents the incoming HTTP request.
+) {
+	// Extract the user ID from the URL path.
+	id, err := strconv.Atoi(r.URL.Path[len("/users/"):])
+	if err != nil {
+		// If the ID is not a valid integer, return a 400 Bad Request error.
+		httperr.WriteProblem(w, http.StatusBadRequest, "Invalid Request", err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.store.Delete(id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			// If the user is not found, return a 404 Not Found error.
+			httperr.WriteProblem(w, http.StatusNotFound, "Not Found", "user not found", r.URL.Path)
+			return
+		}
+		httperr.WriteProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error(), r.URL.Path)
+		return
+	}
+
+	// Respond with a 204 No Content status to indicate success.
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteStore is a Store backed by a SQLite database via database/sql,
+// the backend the service can grow into without touching the HTTP layer.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// newSQLiteStore opens (creating if necessary) the SQLite database at
+// path and ensures the users table exists.
+func newSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS users (
+	id   INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL
+)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+// Close releases the underlying database connection.
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// Get implements Store.
+func (s *sqliteStore) Get(id int) (User, error) {
+	var user User
+	err := s.db.QueryRow(`SELECT name FROM users WHERE id = ?`, id).Scan(&user.Name)
+	if err == sql.ErrNoRows {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, err
+	}
+	user.ID = id
+	return user, nil
+}
+
+// Put implements Store.
+func (s *sqliteStore) Put(id int, user User) error {
+	res, err := s.db.Exec(`UPDATE users SET name = ? WHERE id = ?`, user.Name, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *sqliteStore) Delete(id int) error {
+	res, err := s.db.Exec(`DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Create implements Store.
+func (s *sqliteStore) Create(user User) (int, error) {
+	res, err := s.db.Exec(`INSERT INTO users (name) VALUES (?)`, user.Name)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// List implements Store. opts.Sort is expected to already be whitelisted
+// by the caller, since it is interpolated into the ORDER BY clause.
+func (s *sqliteStore) List(opts ListOptions) ([]User, error) {
+	orderBy := "id"
+	if opts.Sort == "name" {
+		orderBy = "name"
+	}
+
+	query := fmt.Sprintf(`SELECT id, name FROM users ORDER BY %s`, orderBy)
+	if opts.Limit >= 0 {
+		query += fmt.Sprintf(` LIMIT %d OFFSET %d`, opts.Limit, opts.Offset)
+	} else if opts.Offset > 0 {
+		query += fmt.Sprintf(` LIMIT -1 OFFSET %d`, opts.Offset)
+	}
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]User, 0)
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Name); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
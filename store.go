@@ -0,0 +1,27 @@
+package main
+
+import "errors"
+
+// ErrNotFound is returned by Store methods when no user exists for the
+// given id.
+var ErrNotFound = errors.New("user not found")
+
+// ListOptions configures how Store.List orders and paginates its results.
+// A negative Limit means no limit is applied.
+type ListOptions struct {
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// Store is the persistence interface HTTP handlers depend on instead of
+// reaching into package-level state directly. It lets tests inject a fake
+// backend and lets the service grow new backends without touching the
+// HTTP layer.
+type Store interface {
+	Get(id int) (User, error)
+	Put(id int, user User) error
+	Delete(id int) error
+	List(opts ListOptions) ([]User, error)
+	Create(user User) (int, error)
+}